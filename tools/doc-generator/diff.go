@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/grafana/loki/tools/doc-generator/parse"
+)
+
+// loadBlocksSchema reads a schema file previously produced by
+// `-format=json`, as exported by an older version of Loki.
+func loadBlocksSchema(path string) ([]*blockSchema, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading schema file %s: %w", path, err)
+	}
+
+	var blocks []*blockSchema
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return nil, fmt.Errorf("parsing schema file %s: %w", path, err)
+	}
+
+	return blocks, nil
+}
+
+// flattenFields walks a block schema tree and returns every field keyed by
+// its full CLI flag, as exported by `-format=json`. Fields without a flag
+// are skipped, since the flag is the only stable identity across nested
+// blocks whose YAML path can move between releases. Keys are the real flags,
+// not the "<prefix>..." form of the Markdown docs, so a field of a block
+// embedded under several parents is keyed, and reported, once per parent.
+func flattenFields(blocks []*blockSchema) map[string]*fieldSchema {
+	out := map[string]*fieldSchema{}
+	var walk func(*blockSchema)
+	walk = func(block *blockSchema) {
+		for _, field := range block.Fields {
+			if field.Flag != "" {
+				out[field.Flag] = field
+			}
+		}
+		for _, child := range block.Blocks {
+			walk(child)
+		}
+	}
+	for _, block := range blocks {
+		walk(block)
+	}
+	return out
+}
+
+// fieldRename records a flag that disappeared in the new schema and its
+// best-guess replacement, matched by identical description and type.
+type fieldRename struct {
+	From *fieldSchema
+	To   *fieldSchema
+}
+
+// generateDiffMarkdown compares the current config blocks against a
+// previously exported schema and renders a Markdown changelog section
+// listing added, removed, renamed and changed fields, suitable for an
+// upgrade guide's "Configuration changes" section.
+func generateDiffMarkdown(oldBlocks []*blockSchema, newBlocks []*parse.ConfigBlock) string {
+	oldFields := flattenFields(oldBlocks)
+	newFields := flattenFields(buildBlocksSchema(newBlocks, nil))
+
+	var added, removed []string
+	for flag := range newFields {
+		if _, ok := oldFields[flag]; !ok {
+			added = append(added, flag)
+		}
+	}
+	for flag := range oldFields {
+		if _, ok := newFields[flag]; !ok {
+			removed = append(removed, flag)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	renames := matchRenames(removed, added, oldFields, newFields)
+	removed = subtractRenamed(removed, renames, true)
+	added = subtractRenamed(added, renames, false)
+
+	var changedType, changedDefault []string
+	for flag, newField := range newFields {
+		oldField, ok := oldFields[flag]
+		if !ok {
+			continue
+		}
+		if oldField.Type != newField.Type {
+			changedType = append(changedType, fmt.Sprintf("`-%s`: `%s` -> `%s`", flag, oldField.Type, newField.Type))
+		}
+		if oldField.Default != newField.Default {
+			changedDefault = append(changedDefault, fmt.Sprintf("`-%s`: `%s` -> `%s`", flag, oldField.Default, newField.Default))
+		}
+	}
+	sort.Strings(changedType)
+	sort.Strings(changedDefault)
+
+	var sb strings.Builder
+	sb.WriteString("## Configuration changes\n\n")
+	writeFlagList(&sb, "### Added", added, func(flag string) string { return fmt.Sprintf("`-%s`", flag) })
+	writeFlagList(&sb, "### Removed", removed, func(flag string) string { return fmt.Sprintf("`-%s`", flag) })
+	writeRenameList(&sb, renames)
+	writeFlagList(&sb, "### Changed type", changedType, func(s string) string { return s })
+	writeFlagList(&sb, "### Changed default", changedDefault, func(s string) string { return s })
+
+	return sb.String()
+}
+
+// matchRenames pairs up removed/added flags that share the same description
+// and type, which is the best signal we have that a flag was renamed rather
+// than removed and independently added. An empty description is not a
+// signal at all, so it's excluded: otherwise every removed field lacking a
+// description would get paired with an arbitrary added field that also
+// lacks one.
+func matchRenames(removed, added []string, oldFields, newFields map[string]*fieldSchema) []fieldRename {
+	var renames []fieldRename
+	usedAdded := map[string]bool{}
+
+	for _, removedFlag := range removed {
+		oldField := oldFields[removedFlag]
+		if oldField.Description == "" {
+			continue
+		}
+		for _, addedFlag := range added {
+			if usedAdded[addedFlag] {
+				continue
+			}
+			newField := newFields[addedFlag]
+			if newField.Description == oldField.Description && newField.Type == oldField.Type {
+				renames = append(renames, fieldRename{From: oldField, To: newField})
+				usedAdded[addedFlag] = true
+				break
+			}
+		}
+	}
+
+	return renames
+}
+
+func subtractRenamed(flags []string, renames []fieldRename, fromOld bool) []string {
+	renamedFlags := map[string]bool{}
+	for _, r := range renames {
+		if fromOld {
+			renamedFlags[r.From.Flag] = true
+		} else {
+			renamedFlags[r.To.Flag] = true
+		}
+	}
+
+	out := flags[:0:0]
+	for _, flag := range flags {
+		if !renamedFlags[flag] {
+			out = append(out, flag)
+		}
+	}
+	return out
+}
+
+func writeFlagList(sb *strings.Builder, heading string, items []string, format func(string) string) {
+	if len(items) == 0 {
+		return
+	}
+	sb.WriteString(heading + "\n\n")
+	for _, item := range items {
+		sb.WriteString("- " + format(item) + "\n")
+	}
+	sb.WriteString("\n")
+}
+
+func writeRenameList(sb *strings.Builder, renames []fieldRename) {
+	if len(renames) == 0 {
+		return
+	}
+	sb.WriteString("### Renamed\n\n")
+	for _, r := range renames {
+		sb.WriteString(fmt.Sprintf("- `-%s` -> `-%s`\n", r.From.Flag, r.To.Flag))
+	}
+	sb.WriteString("\n")
+}
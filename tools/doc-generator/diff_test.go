@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchRenames_RequiresSameDescriptionAndType(t *testing.T) {
+	oldFields := map[string]*fieldSchema{
+		"old.flag": {Flag: "old.flag", Description: "the thing", Type: "string"},
+		"no.desc":  {Flag: "no.desc", Description: "", Type: "string"},
+	}
+	newFields := map[string]*fieldSchema{
+		"new.flag":      {Flag: "new.flag", Description: "the thing", Type: "string"},
+		"wrong.type":    {Flag: "wrong.type", Description: "the thing", Type: "int"},
+		"other.no.desc": {Flag: "other.no.desc", Description: "", Type: "string"},
+	}
+
+	renames := matchRenames([]string{"old.flag", "no.desc"}, []string{"new.flag", "wrong.type", "other.no.desc"}, oldFields, newFields)
+
+	require.Len(t, renames, 1, "a description-less removed flag must never be paired with a description-less added one")
+	require.Equal(t, "old.flag", renames[0].From.Flag)
+	require.Equal(t, "new.flag", renames[0].To.Flag)
+}
+
+func TestMatchRenames_EachAddedFlagUsedAtMostOnce(t *testing.T) {
+	oldFields := map[string]*fieldSchema{
+		"a": {Flag: "a", Description: "shared", Type: "string"},
+		"b": {Flag: "b", Description: "shared", Type: "string"},
+	}
+	newFields := map[string]*fieldSchema{
+		"c": {Flag: "c", Description: "shared", Type: "string"},
+	}
+
+	renames := matchRenames([]string{"a", "b"}, []string{"c"}, oldFields, newFields)
+
+	require.Len(t, renames, 1, "only one removed flag can claim the single ambiguous added flag")
+}
+
+func TestSubtractRenamed(t *testing.T) {
+	renames := []fieldRename{
+		{From: &fieldSchema{Flag: "old.flag"}, To: &fieldSchema{Flag: "new.flag"}},
+	}
+
+	require.Equal(t, []string{"kept"}, subtractRenamed([]string{"old.flag", "kept"}, renames, true))
+	require.Equal(t, []string{"kept"}, subtractRenamed([]string{"new.flag", "kept"}, renames, false))
+}
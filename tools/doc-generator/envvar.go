@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/loki/tools/doc-generator/parse"
+)
+
+// envVarPrefix is prepended to every derived environment variable name.
+const envVarPrefix = "LOKI_"
+
+// envVarBlockedWarning is printed to stderr, and prepended to the generated
+// Markdown, wherever the envVar field this file derives reaches an output a
+// human or tool might otherwise take as evidence that Loki supports
+// namsral/flag-style environment overrides. It does not yet: this request
+// is blocked on pkg/loki, which isn't part of this checkout, so the actual
+// parsing (and its env < config file < CLI flag precedence) can't be added
+// here. Remove this warning (and the "candidate" language below) once that
+// wiring lands in pkg/loki.
+const envVarBlockedWarning = "NOTE: the environment variable names below are a naming convention only; pkg/loki does not parse them as overrides yet. Once it does, the intended precedence is environment variable < config file < CLI flag."
+
+// envVarName derives the candidate environment variable name a CLI flag
+// would have to use for a namsral/flag-style environment override, e.g.
+// "server.http-listen-port" becomes "LOKI_SERVER_HTTP_LISTEN_PORT".
+//
+// This is a naming convention only, see envVarBlockedWarning: pkg/loki does
+// not parse these environment variables today, so the derived name is not
+// an active override path.
+func envVarName(flag string) string {
+	replaced := strings.NewReplacer(".", "_", "-", "_").Replace(flag)
+	return envVarPrefix + strings.ToUpper(replaced)
+}
+
+// collectEnvVars derives the candidate environment variable name for every
+// field with a CLI flag, keyed by that flag. It must run on blocks that have
+// been through annotateFlagPrefix but not yet stripFlagPrefixes, so flags
+// are still in their real (not "<prefix>...") form.
+//
+// Collapsing "." and "-" into "_" means two distinct flags can derive the
+// same name (e.g. "foo.bar-baz" and "foo-bar.baz" both become
+// LOKI_FOO_BAR_BAZ); an error is returned in that case rather than silently
+// publishing one name for two flags.
+func collectEnvVars(blocks []*parse.ConfigBlock) (map[string]string, error) {
+	flagToEnvVar := map[string]string{}
+	envVarToFlag := map[string]string{}
+
+	var walk func(*parse.ConfigBlock) error
+	walk = func(block *parse.ConfigBlock) error {
+		for _, entry := range block.Entries {
+			switch entry.Kind {
+			case parse.KindBlock:
+				// Skip root blocks
+				if !entry.Root {
+					if err := walk(entry.Block); err != nil {
+						return err
+					}
+				}
+			case parse.KindField:
+				if entry.FieldFlag == "" {
+					continue
+				}
+				envVar := envVarName(entry.FieldFlag)
+				if other, ok := envVarToFlag[envVar]; ok && other != entry.FieldFlag {
+					return fmt.Errorf("flags -%s and -%s both derive the environment variable %s", other, entry.FieldFlag, envVar)
+				}
+				envVarToFlag[envVar] = entry.FieldFlag
+				flagToEnvVar[entry.FieldFlag] = envVar
+			}
+		}
+		return nil
+	}
+
+	for _, block := range blocks {
+		if err := walk(block); err != nil {
+			return nil, err
+		}
+	}
+
+	return flagToEnvVar, nil
+}
+
+// annotateEnvVarDescriptions appends each field's candidate environment
+// variable name to its description, so it shows up in the existing
+// per-field Markdown table instead of a separate, easy-to-miss section. See
+// envVarBlockedWarning: this is the name pkg/loki would have to parse, not
+// proof that it does.
+func annotateEnvVarDescriptions(blocks []*parse.ConfigBlock, envVars map[string]string) {
+	for _, block := range blocks {
+		for _, entry := range block.Entries {
+			switch entry.Kind {
+			case parse.KindBlock:
+				if !entry.Root {
+					annotateEnvVarDescriptions([]*parse.ConfigBlock{entry.Block}, envVars)
+				}
+			case parse.KindField:
+				if envVar, ok := envVars[entry.FieldFlag]; ok {
+					entry.FieldDesc = strings.TrimSpace(entry.FieldDesc) + fmt.Sprintf(" Candidate environment variable name: `%s`.", envVar)
+				}
+			}
+		}
+	}
+}
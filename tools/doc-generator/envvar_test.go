@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/tools/doc-generator/parse"
+)
+
+func TestEnvVarName(t *testing.T) {
+	for _, tc := range []struct {
+		flag string
+		want string
+	}{
+		{"server.http-listen-port", "LOKI_SERVER_HTTP_LISTEN_PORT"},
+		{"auth.enabled", "LOKI_AUTH_ENABLED"},
+		{"ingester.lifecycler.ID", "LOKI_INGESTER_LIFECYCLER_ID"},
+	} {
+		require.Equal(t, tc.want, envVarName(tc.flag))
+	}
+}
+
+func TestCollectEnvVars_SkipsRootAndFlaglessEntries(t *testing.T) {
+	nested := &parse.ConfigBlock{
+		Name: "nested",
+		Entries: []*parse.ConfigEntry{
+			{Kind: parse.KindField, Name: "a", FieldFlag: "root.nested.a"},
+		},
+	}
+	rootChild := &parse.ConfigBlock{
+		Name: "ring",
+		Entries: []*parse.ConfigEntry{
+			{Kind: parse.KindField, Name: "b", FieldFlag: "root.ring.b"},
+		},
+	}
+	root := &parse.ConfigBlock{
+		Name: "root",
+		Entries: []*parse.ConfigEntry{
+			{Kind: parse.KindField, Name: "noflag"},
+			{Kind: parse.KindBlock, Name: "nested", Block: nested},
+			{Kind: parse.KindBlock, Name: "ring", Block: rootChild, Root: true},
+		},
+	}
+
+	envVars, err := collectEnvVars([]*parse.ConfigBlock{root})
+	require.NoError(t, err)
+
+	require.Contains(t, envVars, "root.nested.a")
+	require.NotContains(t, envVars, "", "fields without a CLI flag get no environment variable")
+	require.NotContains(t, envVars, "root.ring.b", "root blocks are documented separately, not recursed into here")
+}
+
+func TestCollectEnvVars_CollisionIsAnError(t *testing.T) {
+	block := &parse.ConfigBlock{
+		Name: "root",
+		Entries: []*parse.ConfigEntry{
+			{Kind: parse.KindField, Name: "a", FieldFlag: "foo.bar-baz"},
+			{Kind: parse.KindField, Name: "b", FieldFlag: "foo-bar.baz"},
+		},
+	}
+
+	_, err := collectEnvVars([]*parse.ConfigBlock{block})
+	require.Error(t, err)
+}
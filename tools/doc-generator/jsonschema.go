@@ -0,0 +1,253 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/grafana/loki/tools/doc-generator/parse"
+)
+
+// jsonSchemaDraft is the JSON Schema draft implemented by generateJSONSchema.
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// knownEnums lists config fields whose valid values are a closed set, keyed
+// by a block-qualified dotted path (e.g. "ingester.chunk_encoding") rather
+// than the bare field name. Leaf names like "compression" are reused by
+// unrelated fields with different valid sets (gRPC client compression vs.
+// chunk compression, for example), so keying by the bare name would apply
+// one field's enum to all of them.
+//
+// schema_config.store is deliberately not listed here: the store field
+// actually lives under schema_config.configs[].store, one per period
+// config, but schema_config.configs is a YAML list rather than a
+// flag-bound struct field, so this package's path-based model has no
+// entry to key it by. Add it once propForBlock can address list elements.
+//
+// gRPC client compression (grpc_compression on the shared gRPC client config
+// block) is deliberately not listed here either, for a related reason: that
+// block is embedded at several different real paths (e.g. under the
+// ingester, querier and ruler clients), each a distinct occurrence with its
+// own dotted path, so there's no single block-qualified key that would
+// cover all of them the way "ingester.chunk_encoding" covers its one,
+// unshared field. Add it once knownEnums can key by block type + field name
+// for fields on a shared block, rather than by one occurrence's full path.
+var knownEnums = map[string][]interface{}{
+	"auth_enabled":                               {true, false},
+	"storage_config.boltdb_shipper.shared_store": {"azure", "gcs", "s3", "swift", "filesystem", "cos", "alibabacloud"},
+	"ingester.chunk_encoding":                    {"gzip", "lz4-64k", "lz4-256k", "lz4-1M", "lz4", "snappy", "flate", "zstd"},
+}
+
+// jsonSchemaProp is a single JSON Schema node: an object, a scalar field, or
+// a reference to a shared definition.
+type jsonSchemaProp struct {
+	Ref         string                     `json:"$ref,omitempty"`
+	Type        string                     `json:"type,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	Default     interface{}                `json:"default,omitempty"`
+	Enum        []interface{}              `json:"enum,omitempty"`
+	Properties  map[string]*jsonSchemaProp `json:"properties,omitempty"`
+}
+
+// jsonSchema is the root Draft-07 document produced for loki.Config.
+type jsonSchema struct {
+	Schema      string                     `json:"$schema"`
+	Type        string                     `json:"type"`
+	Properties  map[string]*jsonSchemaProp `json:"properties,omitempty"`
+	Definitions map[string]*jsonSchemaProp `json:"definitions,omitempty"`
+}
+
+// countBlockNames counts how many times each block name occurs across the
+// whole tree, so that blocks appearing more than once can be emitted once
+// under "definitions" and referenced everywhere else via $ref. Recursion
+// into Root entries is skipped here (unlike in propForBlock, which has to
+// reach their real nested path): every occurrence of a Root block is already
+// its own entry in the top-level `blocks` slice, so recursing into it too
+// would both double-count it and, for any cycle among root blocks, recurse
+// forever.
+func countBlockNames(blocks []*parse.ConfigBlock, counts map[string]int) {
+	for _, block := range blocks {
+		counts[block.Name]++
+		for _, entry := range block.Entries {
+			if entry.Kind == parse.KindBlock && !entry.Root {
+				countBlockNames([]*parse.ConfigBlock{entry.Block}, counts)
+			}
+		}
+	}
+}
+
+// jsonSchemaBuilder accumulates shared block definitions while the schema
+// tree is being built.
+type jsonSchemaBuilder struct {
+	blockCounts map[string]int
+	definitions map[string]*jsonSchemaProp
+}
+
+// coerceDefault converts a FieldDefault string into a value matching the
+// field's declared JSON Schema type, so the emitted schema doesn't contain
+// e.g. {"type":"integer","default":"30"} or {"type":"boolean","default":"false"},
+// which validators reject as a type mismatch. Defaults that can't be parsed
+// as their declared type are dropped rather than published as a lie.
+func coerceDefault(fieldType, raw string) (interface{}, bool) {
+	if raw == "" {
+		return nil, false
+	}
+	switch jsonSchemaType(fieldType) {
+	case "boolean":
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, false
+		}
+		return v, true
+	case "integer":
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		return v, true
+	case "number":
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, false
+		}
+		return v, true
+	default:
+		return raw, true
+	}
+}
+
+func (b *jsonSchemaBuilder) propForField(entry *parse.ConfigEntry, path string) *jsonSchemaProp {
+	prop := &jsonSchemaProp{
+		Type:        jsonSchemaType(entry.FieldType),
+		Description: entry.FieldDesc,
+	}
+	if v, ok := coerceDefault(entry.FieldType, entry.FieldDefault); ok {
+		prop.Default = v
+	}
+	if enum, ok := knownEnums[path]; ok {
+		prop.Enum = enum
+	}
+	return prop
+}
+
+func (b *jsonSchemaBuilder) propForBlock(block *parse.ConfigBlock, path string) *jsonSchemaProp {
+	shared := b.blockCounts[block.Name] > 1
+
+	if shared {
+		if _, ok := b.definitions[block.Name]; ok {
+			return &jsonSchemaProp{Ref: "#/definitions/" + block.Name}
+		}
+		// Reserve the slot before recursing, in case of self-referential blocks.
+		b.definitions[block.Name] = &jsonSchemaProp{}
+	}
+
+	obj := &jsonSchemaProp{Type: "object", Description: block.Desc, Properties: map[string]*jsonSchemaProp{}}
+	for _, entry := range block.Entries {
+		fieldPath := entry.Name
+		if path != "" {
+			fieldPath = path + "." + entry.Name
+		}
+
+		switch entry.Kind {
+		case parse.KindBlock:
+			// Unlike the Markdown walkers, we must not skip Root entries
+			// here: "Root" only means the block is documented once and
+			// $ref'd from everywhere it's embedded (see propForBlock's own
+			// "shared" handling below), not that it lives at the document
+			// root. A block embedded deep in the tree, e.g. the ring config
+			// at ingester.lifecycler.ring, still has to appear at that real
+			// path for the schema to validate loki.yaml; skipping it here
+			// would silently drop it instead.
+			obj.Properties[entry.Name] = b.propForBlock(entry.Block, fieldPath)
+		case parse.KindField:
+			obj.Properties[entry.Name] = b.propForField(entry, fieldPath)
+		}
+	}
+
+	if shared {
+		b.definitions[block.Name] = obj
+		return &jsonSchemaProp{Ref: "#/definitions/" + block.Name}
+	}
+
+	return obj
+}
+
+// jsonSchemaType maps a Go field type (as surfaced by parse.ConfigEntry) to
+// its closest JSON Schema primitive. Anything unrecognised is left as
+// "string", which is the most permissive lossy fallback.
+func jsonSchemaType(fieldType string) string {
+	switch fieldType {
+	case "bool":
+		return "boolean"
+	case "int", "int32", "int64", "uint", "uint32", "uint64":
+		return "integer"
+	case "float32", "float64":
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+// findNestedBlocks returns the set of blocks, among those reachable from
+// `blocks`, that are embedded as a Root-flagged child entry somewhere in the
+// tree. parse.Config returns one *parse.ConfigBlock per occurrence of a
+// block type documented at root level, but "documented at root level" is a
+// Markdown-rendering concept (display the block once, link to it from every
+// parent that embeds it), not a statement about where it nests in loki.yaml:
+// a config block shared by several parents (e.g. the ring config embedded
+// under ingester, distributor, compactor, ...) shows up once per parent in
+// `blocks`, each instance still living at that parent's real nested path.
+// Only the blocks that are genuinely never any other block's child entry
+// are real top-level keys of the document.
+func findNestedBlocks(blocks []*parse.ConfigBlock) map[*parse.ConfigBlock]bool {
+	nested := map[*parse.ConfigBlock]bool{}
+	var walk func(*parse.ConfigBlock)
+	walk = func(block *parse.ConfigBlock) {
+		for _, entry := range block.Entries {
+			if entry.Kind != parse.KindBlock {
+				continue
+			}
+			if entry.Root {
+				nested[entry.Block] = true
+			} else {
+				walk(entry.Block)
+			}
+		}
+	}
+	for _, block := range blocks {
+		walk(block)
+	}
+	return nested
+}
+
+// generateJSONSchema builds a Draft-07 JSON Schema describing loki.yaml from
+// the parsed config blocks.
+func generateJSONSchema(blocks []*parse.ConfigBlock) ([]byte, error) {
+	builder := &jsonSchemaBuilder{
+		blockCounts: map[string]int{},
+		definitions: map[string]*jsonSchemaProp{},
+	}
+	countBlockNames(blocks, builder.blockCounts)
+	nested := findNestedBlocks(blocks)
+
+	root := &jsonSchema{
+		Schema:     jsonSchemaDraft,
+		Type:       "object",
+		Properties: map[string]*jsonSchemaProp{},
+	}
+
+	for _, block := range blocks {
+		// Blocks that are some other block's nested child are added to the
+		// schema from that parent's real path instead; adding them again
+		// here would invent a bogus root-level sibling for them.
+		if nested[block] {
+			continue
+		}
+		root.Properties[block.Name] = builder.propForBlock(block, block.Name)
+	}
+
+	root.Definitions = builder.definitions
+
+	return json.MarshalIndent(root, "", "  ")
+}
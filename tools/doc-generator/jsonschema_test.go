@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/tools/doc-generator/parse"
+)
+
+func TestCoerceDefault(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		fieldType string
+		raw       string
+		want      interface{}
+		ok        bool
+	}{
+		{"empty is dropped", "int", "", nil, false},
+		{"valid bool", "bool", "false", false, true},
+		{"valid int", "int64", "30", int64(30), true},
+		{"valid float", "float64", "0.5", 0.5, true},
+		{"unparseable int is dropped, not published as a lie", "int", "not-a-number", nil, false},
+		{"unrecognised type falls back to the raw string", "duration", "30s", "30s", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := coerceDefault(tc.fieldType, tc.raw)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestJSONSchemaType(t *testing.T) {
+	require.Equal(t, "boolean", jsonSchemaType("bool"))
+	require.Equal(t, "integer", jsonSchemaType("int64"))
+	require.Equal(t, "number", jsonSchemaType("float64"))
+	require.Equal(t, "string", jsonSchemaType("duration"))
+}
+
+// TestGenerateJSONSchema_NestsSharedBlockAtRealPath is a regression test for
+// a block shared by several parents (e.g. the ring config embedded under
+// ingester and distributor): it must appear nested under each parent's real
+// key via $ref, not as a bogus sibling of the document root.
+func TestGenerateJSONSchema_NestsSharedBlockAtRealPath(t *testing.T) {
+	ringField := func(parent string) *parse.ConfigEntry {
+		return &parse.ConfigEntry{Kind: parse.KindField, Name: "instance-id", FieldFlag: parent + ".ring.instance-id", FieldType: "string"}
+	}
+	ringUnderIngester := &parse.ConfigBlock{Name: "ring", Entries: []*parse.ConfigEntry{ringField("ingester")}}
+	ringUnderDistributor := &parse.ConfigBlock{Name: "ring", Entries: []*parse.ConfigEntry{ringField("distributor")}}
+
+	ingester := &parse.ConfigBlock{
+		Name: "ingester",
+		Entries: []*parse.ConfigEntry{
+			{Kind: parse.KindBlock, Name: "ring", Block: ringUnderIngester, Root: true},
+		},
+	}
+	distributor := &parse.ConfigBlock{
+		Name: "distributor",
+		Entries: []*parse.ConfigEntry{
+			{Kind: parse.KindBlock, Name: "ring", Block: ringUnderDistributor, Root: true},
+		},
+	}
+
+	raw, err := generateJSONSchema([]*parse.ConfigBlock{ingester, distributor, ringUnderIngester, ringUnderDistributor})
+	require.NoError(t, err)
+
+	var schema jsonSchema
+	require.NoError(t, json.Unmarshal(raw, &schema))
+
+	require.Contains(t, schema.Properties, "ingester")
+	require.Contains(t, schema.Properties, "distributor")
+	require.NotContains(t, schema.Properties, "ring", "the shared ring block must not be hoisted to a root-level sibling")
+
+	ingesterRing := schema.Properties["ingester"].Properties["ring"]
+	require.NotNil(t, ingesterRing)
+	require.Equal(t, "#/definitions/ring", ingesterRing.Ref, "a shared block nested under a parent must be $ref'd, not skipped or inlined twice")
+	require.Contains(t, schema.Definitions, "ring")
+}
@@ -38,6 +38,13 @@ func removeFlagPrefix(block *parse.ConfigBlock, prefix string) {
 	}
 }
 
+// annotateFlagPrefix finds the canonical CLI flags prefix of each duplicated
+// block and stores it (and all sibling prefixes) on the block, without
+// touching any entry's FieldFlag. Callers that need the deduplicated,
+// prefix-stripped form (e.g. the Markdown docs) must call stripFlagPrefixes
+// afterwards; callers that need the real, usable CLI flags (e.g. the
+// JSON/YAML/JSON-Schema exports) can read FlagsPrefix/FlagsPrefixes directly
+// instead.
 func annotateFlagPrefix(blocks []*parse.ConfigBlock) {
 	// Find duplicated blocks
 	groups := map[string][]*parse.ConfigBlock{}
@@ -83,9 +90,14 @@ func annotateFlagPrefix(blocks []*parse.ConfigBlock) {
 			block.FlagsPrefixes = allPrefixes
 		}
 	}
+}
 
-	// Finally, we can remove the CLI flags prefix from the blocks
-	// which have one annotated.
+// stripFlagPrefixes removes the CLI flags prefix from every block that has
+// one annotated, replacing it with the "<prefix>" placeholder. This is only
+// safe for presentation formats (Markdown) that display a duplicated block
+// once; formats meant for machine consumption of the real CLI flags must not
+// call this.
+func stripFlagPrefixes(blocks []*parse.ConfigBlock) {
 	for _, block := range blocks {
 		if block.FlagsPrefix != "" {
 			removeFlagPrefix(block, block.FlagsPrefix)
@@ -99,9 +111,95 @@ func generateBlocksMarkdown(blocks []*parse.ConfigBlock) string {
 	return md.string()
 }
 
+// Supported values for the -format flag.
+const (
+	formatMarkdown   = "markdown"
+	formatJSON       = "json"
+	formatYAML       = "yaml"
+	formatJSONSchema = "jsonschema"
+)
+
 func main() {
+	format := flag.String("format", formatMarkdown, "Output format: markdown, json, yaml or jsonschema")
+	diffAgainst := flag.String("diff-against", "", "Path to a schema file (as produced by -format=json) from a previous release, to generate a Configuration changes report instead of the usual output")
+
 	// Parse the generator flags.
 	flag.Parse()
+
+	if *diffAgainst != "" {
+		if flag.NArg() != 0 {
+			fmt.Fprintf(os.Stderr, "Usage: doc-generator -diff-against=<path>\n")
+			os.Exit(1)
+		}
+
+		oldBlocks, err := loadBlocksSchema(*diffAgainst)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "An error occurred while loading the previous schema: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		cfg := &loki.Config{}
+		flags := parse.Flags(cfg)
+
+		blocks, err := parse.Config(cfg, flags, parse.RootBlocks)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "An error occurred while generating the doc: %s\n", err.Error())
+			os.Exit(1)
+		}
+		annotateFlagPrefix(blocks)
+
+		fmt.Println(generateDiffMarkdown(oldBlocks, blocks))
+		return
+	}
+
+	if *format == formatJSON || *format == formatYAML || *format == formatJSONSchema {
+		if flag.NArg() != 0 {
+			fmt.Fprintf(os.Stderr, "Usage: doc-generator -format=%s\n", *format)
+			os.Exit(1)
+		}
+
+		cfg := &loki.Config{}
+		flags := parse.Flags(cfg)
+
+		blocks, err := parse.Config(cfg, flags, parse.RootBlocks)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "An error occurred while generating the doc: %s\n", err.Error())
+			os.Exit(1)
+		}
+		// Only annotate the prefixes here: the real CLI flags must reach
+		// these machine-readable formats unmodified, since config
+		// validators and IDE completion need an actually usable -flag, not
+		// the "<prefix>..." placeholder used for display purposes.
+		annotateFlagPrefix(blocks)
+
+		var envVars map[string]string
+		if *format == formatJSON || *format == formatYAML {
+			envVars, err = collectEnvVars(blocks)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "An error occurred while deriving environment variable names: %s\n", err.Error())
+				os.Exit(1)
+			}
+			fmt.Fprintln(os.Stderr, envVarBlockedWarning)
+		}
+
+		var out []byte
+		switch *format {
+		case formatJSON:
+			out, err = generateBlocksJSON(blocks, envVars)
+		case formatYAML:
+			out, err = generateBlocksYAML(blocks, envVars)
+		case formatJSONSchema:
+			out, err = generateJSONSchema(blocks)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "An error occurred while serializing the doc: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Println(string(out))
+		return
+	}
+
 	if flag.NArg() != 1 {
 		fmt.Fprintf(os.Stderr, "Usage: doc-generator template-file")
 		os.Exit(1)
@@ -122,17 +220,30 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Annotate the flags prefix for each root block, and remove the
-	// prefix wherever encountered in the config blocks.
+	// Annotate the flags prefix for each root block before deriving the env
+	// var names, so the names are computed from the real CLI flags rather
+	// than the "<prefix>..." placeholder that stripFlagPrefixes leaves
+	// behind.
 	annotateFlagPrefix(blocks)
 
+	envVars, err := collectEnvVars(blocks)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred while deriving environment variable names: %s\n", err.Error())
+		os.Exit(1)
+	}
+	annotateEnvVarDescriptions(blocks, envVars)
+
+	// Remove the CLI flags prefix wherever encountered in the config
+	// blocks, now that it's no longer needed for the env var names above.
+	stripFlagPrefixes(blocks)
+
 	// Generate documentation markdown.
 	data := struct {
 		ConfigFile           string
 		GeneratedFileWarning string
 	}{
 		GeneratedFileWarning: "<!-- DO NOT EDIT THIS FILE - This file has been automatically generated from its .template, regenerate with `make doc` from root directory. -->",
-		ConfigFile:           generateBlocksMarkdown(blocks),
+		ConfigFile:           "> " + envVarBlockedWarning + "\n\n" + generateBlocksMarkdown(blocks),
 	}
 
 	// Load the template file.
@@ -149,4 +260,4 @@ func main() {
 		fmt.Fprintf(os.Stderr, "An error occurred while executing the template %s: %s\n", templatePath, err.Error())
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package main
+
+import (
+	"encoding/json"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/grafana/loki/tools/doc-generator/parse"
+)
+
+// fieldSchema is the machine-readable representation of a single config
+// field, suitable for JSON/YAML serialisation. It mirrors the information
+// already rendered into the Markdown docs by generateBlocksMarkdown.
+type fieldSchema struct {
+	Name string `json:"name" yaml:"name"`
+	Flag string `json:"flag,omitempty" yaml:"flag,omitempty"`
+	// EnvVar is the candidate environment variable name derived from Flag;
+	// see envVarName. It is not yet an active override path.
+	EnvVar      string `json:"envVar,omitempty" yaml:"envVar,omitempty"`
+	Type        string `json:"type,omitempty" yaml:"type,omitempty"`
+	Default     string `json:"default,omitempty" yaml:"default,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// blockSchema is the machine-readable representation of a config block
+// (either a root block or a nested one referenced from a parent block).
+type blockSchema struct {
+	Name          string         `json:"name" yaml:"name"`
+	Desc          string         `json:"desc,omitempty" yaml:"desc,omitempty"`
+	FlagsPrefix   string         `json:"flagsPrefix,omitempty" yaml:"flagsPrefix,omitempty"`
+	FlagsPrefixes []string       `json:"flagsPrefixes,omitempty" yaml:"flagsPrefixes,omitempty"`
+	Fields        []*fieldSchema `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Blocks        []*blockSchema `json:"blocks,omitempty" yaml:"blocks,omitempty"`
+}
+
+// buildBlockSchema walks a parsed config block and converts it into its
+// serialisable form, recursing into nested (non-root) blocks.
+//
+// blocks passed here must have gone through annotateFlagPrefix but NOT
+// stripFlagPrefixes: downstream consumers of this schema (config
+// validators, IDE completion) need the real, usable CLI flags, not the
+// "<prefix>..." placeholder used for display in the deduplicated Markdown
+// docs. The FlagsPrefix/FlagsPrefixes fields already carry that
+// deduplication information for callers who need it.
+//
+// envVars maps a flag to its candidate environment variable name, as
+// returned by collectEnvVars; it may be nil if that information isn't
+// needed by the caller.
+func buildBlockSchema(block *parse.ConfigBlock, envVars map[string]string) *blockSchema {
+	out := &blockSchema{
+		Name:          block.Name,
+		Desc:          block.Desc,
+		FlagsPrefix:   block.FlagsPrefix,
+		FlagsPrefixes: block.FlagsPrefixes,
+	}
+
+	for _, entry := range block.Entries {
+		switch entry.Kind {
+		case parse.KindBlock:
+			if !entry.Root {
+				out.Blocks = append(out.Blocks, buildBlockSchema(entry.Block, envVars))
+			}
+		case parse.KindField:
+			out.Fields = append(out.Fields, &fieldSchema{
+				Name:        entry.Name,
+				Flag:        entry.FieldFlag,
+				EnvVar:      envVars[entry.FieldFlag],
+				Type:        entry.FieldType,
+				Default:     entry.FieldDefault,
+				Description: entry.FieldDesc,
+			})
+		}
+	}
+
+	return out
+}
+
+func buildBlocksSchema(blocks []*parse.ConfigBlock, envVars map[string]string) []*blockSchema {
+	out := make([]*blockSchema, 0, len(blocks))
+	for _, block := range blocks {
+		out = append(out, buildBlockSchema(block, envVars))
+	}
+	return out
+}
+
+func generateBlocksJSON(blocks []*parse.ConfigBlock, envVars map[string]string) ([]byte, error) {
+	return json.MarshalIndent(buildBlocksSchema(blocks, envVars), "", "  ")
+}
+
+func generateBlocksYAML(blocks []*parse.ConfigBlock, envVars map[string]string) ([]byte, error) {
+	return yaml.Marshal(buildBlocksSchema(blocks, envVars))
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/tools/doc-generator/parse"
+)
+
+func TestBuildBlockSchema_SkipsRootNestedBlocks(t *testing.T) {
+	inner := &parse.ConfigBlock{
+		Name: "inner",
+		Entries: []*parse.ConfigEntry{
+			{Kind: parse.KindField, Name: "field", FieldFlag: "outer.inner.field", FieldType: "string"},
+		},
+	}
+	rootChild := &parse.ConfigBlock{
+		Name: "ring",
+		Entries: []*parse.ConfigEntry{
+			{Kind: parse.KindField, Name: "field", FieldFlag: "outer.ring.field", FieldType: "string"},
+		},
+	}
+	outer := &parse.ConfigBlock{
+		Name: "outer",
+		Entries: []*parse.ConfigEntry{
+			{Kind: parse.KindBlock, Name: "inner", Block: inner},
+			{Kind: parse.KindBlock, Name: "ring", Block: rootChild, Root: true},
+		},
+	}
+
+	out := buildBlockSchema(outer, nil)
+
+	require.Len(t, out.Blocks, 1, "only the non-root nested block should be inlined")
+	require.Equal(t, "inner", out.Blocks[0].Name)
+}
+
+func TestBuildBlockSchema_PopulatesEnvVar(t *testing.T) {
+	block := &parse.ConfigBlock{
+		Name: "server",
+		Entries: []*parse.ConfigEntry{
+			{Kind: parse.KindField, Name: "http-listen-port", FieldFlag: "server.http-listen-port", FieldType: "int"},
+		},
+	}
+	envVars := map[string]string{"server.http-listen-port": "LOKI_SERVER_HTTP_LISTEN_PORT"}
+
+	out := buildBlockSchema(block, envVars)
+
+	require.Len(t, out.Fields, 1)
+	require.Equal(t, "LOKI_SERVER_HTTP_LISTEN_PORT", out.Fields[0].EnvVar)
+}
+
+func TestBuildBlocksSchema_PreservesDuplicateNames(t *testing.T) {
+	// Two occurrences of the same shared block type, e.g. ring config
+	// embedded under two different parents, must both survive: the slice
+	// format (unlike the JSON Schema's Properties map) has no uniqueness
+	// requirement on Name.
+	a := &parse.ConfigBlock{Name: "ring", FlagsPrefix: "ingester.lifecycler.ring."}
+	b := &parse.ConfigBlock{Name: "ring", FlagsPrefix: "distributor.ring."}
+
+	out := buildBlocksSchema([]*parse.ConfigBlock{a, b}, nil)
+
+	require.Len(t, out, 2)
+	require.Equal(t, "ingester.lifecycler.ring.", out[0].FlagsPrefix)
+	require.Equal(t, "distributor.ring.", out[1].FlagsPrefix)
+}